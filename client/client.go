@@ -0,0 +1,179 @@
+// Package client provides a typed Go client for go-notification-agent's unix
+// socket protocol, so other tools (rofi menus, status bars, cron jobs) can
+// call the running agent directly instead of shelling out with `nc -U`.
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DefaultSocket is the unix socket path the agent listens on by default.
+const DefaultSocket = "/tmp/go-notification-agent.sock"
+
+// Client talks to a running go-notification-agent over its unix socket. One
+// connection is made per call, matching how the agent serves requests.
+type Client struct {
+	SocketPath string
+	Timeout    time.Duration
+}
+
+// New creates a Client targeting the given socket path.
+func New(socketPath string) *Client {
+	return &Client{SocketPath: socketPath, Timeout: 5 * time.Second}
+}
+
+type request struct {
+	Cmd  string      `json:"cmd"`
+	Args interface{} `json:"args,omitempty"`
+}
+
+type response struct {
+	Ok    bool            `json:"ok"`
+	Error string          `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// call sends cmd with args and decodes the response's data field into out,
+// which may be nil when the caller doesn't need the payload.
+func (c *Client) call(cmd string, args interface{}, out interface{}) error {
+	conn, err := net.DialTimeout("unix", c.SocketPath, c.Timeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", c.SocketPath, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.Timeout))
+
+	b, err := json.Marshal(request{Cmd: cmd, Args: args})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	if _, err := conn.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var resp response
+	if err := json.Unmarshal([]byte(line), &resp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if !resp.Ok {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	if out != nil && len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("failed to decode response data: %w", err)
+		}
+	}
+	return nil
+}
+
+// Notification mirrors notifier.Notification without requiring callers to
+// import the agent's module.
+type Notification struct {
+	Title     string    `json:"Title"`
+	Message   string    `json:"Message"`
+	Urgency   int       `json:"Urgency"`
+	CreatedOn time.Time `json:"CreatedOn"`
+}
+
+// Record is a single entry in the persisted notification history.
+type Record struct {
+	ID        uint64            `json:"id"`
+	Title     string            `json:"title"`
+	Message   string            `json:"message"`
+	Urgency   int               `json:"urgency"`
+	App       string            `json:"app"`
+	CreatedOn time.Time         `json:"createdOn"`
+	Delivery  map[string]string `json:"delivery"`
+	Acked     bool              `json:"acked"`
+	Count     int               `json:"count"`
+}
+
+// Stats is a summary of everything the history store has captured.
+type Stats struct {
+	Total     int            `json:"total"`
+	Unacked   int            `json:"unacked"`
+	ByApp     map[string]int `json:"byApp"`
+	ByUrgency map[string]int `json:"byUrgency"`
+}
+
+// Capabilities describes what a running agent supports.
+type Capabilities struct {
+	Version   int      `json:"version"`
+	Commands  []string `json:"commands"`
+	Notifiers []string `json:"notifiers"`
+}
+
+// HistoryQuery filters the History call. Since is a duration string (e.g.
+// "1h30m"); an empty field leaves that filter unapplied.
+type HistoryQuery struct {
+	Since   string `json:"since,omitempty"`
+	Urgency string `json:"urgency,omitempty"`
+	App     string `json:"app,omitempty"`
+}
+
+// Pop drops the oldest bar notification.
+func (c *Client) Pop() error { return c.call("pop", nil, nil) }
+
+// Clear drops every tracked bar notification.
+func (c *Client) Clear() error { return c.call("clear", nil, nil) }
+
+// GetList returns the notifications currently tracked by the bar.
+func (c *Client) GetList() ([]Notification, error) {
+	var out []Notification
+	err := c.call("get-list", nil, &out)
+	return out, err
+}
+
+// ReloadPlugins hot-swaps the notifier plugins loaded from disk.
+func (c *Client) ReloadPlugins() error { return c.call("reload-plugins", nil, nil) }
+
+// History queries the persisted notification log, most recent first.
+func (c *Client) History(q HistoryQuery) ([]Record, error) {
+	var out []Record
+	err := c.call("history", q, &out)
+	return out, err
+}
+
+// Ack marks a history record as read.
+func (c *Client) Ack(id uint64) error {
+	return c.call("ack", struct {
+		ID uint64 `json:"id"`
+	}{ID: id}, nil)
+}
+
+// Stats fetches a summary of the persisted notification history.
+func (c *Client) Stats() (Stats, error) {
+	var out Stats
+	err := c.call("stats", nil, &out)
+	return out, err
+}
+
+// DND toggles or schedules the agent's quiet-hours override. state is "on",
+// "off" or "until"; until is a duration string (e.g. "1h30m"), required only
+// for "until".
+func (c *Client) DND(state, until string) error {
+	return c.call("dnd", struct {
+		State string `json:"state"`
+		Until string `json:"until,omitempty"`
+	}{State: state, Until: until}, nil)
+}
+
+// Capabilities returns the protocol version, supported commands and loaded
+// notifiers of the running agent.
+func (c *Client) Capabilities() (Capabilities, error) {
+	var out Capabilities
+	err := c.call("capabilities", nil, &out)
+	return out, err
+}
+
+// Exit asks the agent to shut down gracefully.
+func (c *Client) Exit() error { return c.call("exit", nil, nil) }
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Neokil/go-notification-agent/notifier"
+)
+
+// Rule maps notifications matching the given filters to an ordered list of
+// notifier names. Rules are evaluated in file order and the first match
+// wins, similar to gh-not's group -> filter -> actor pipeline. A Rule with
+// no filters at all matches everything.
+type Rule struct {
+	Urgencies    []string `json:"urgencies,omitempty"`
+	TitleRegex   string   `json:"titleRegex,omitempty"`
+	MessageRegex string   `json:"messageRegex,omitempty"`
+	Notifiers    []string `json:"notifiers"`
+
+	titleRegex   *regexp.Regexp
+	messageRegex *regexp.Regexp
+}
+
+// BellConfig configures the optional audio bell notifier.
+type BellConfig struct {
+	SoundFile string `json:"soundFile"`
+}
+
+// EmailConfig configures the optional SMTP forwarder.
+type EmailConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// WebhookConfig configures the optional HTTP webhook forwarder.
+type WebhookConfig struct {
+	URL string `json:"url"`
+}
+
+// NotifiersConfig holds the settings for the notifiers that need them.
+// Notifiers left nil are not instantiated.
+type NotifiersConfig struct {
+	Bell    *BellConfig    `json:"bell,omitempty"`
+	Email   *EmailConfig   `json:"email,omitempty"`
+	Webhook *WebhookConfig `json:"webhook,omitempty"`
+}
+
+// RateLimitConfig bounds how many notifications a single app may trigger.
+type RateLimitConfig struct {
+	PerMinute int `json:"perMinute"`
+	Burst     int `json:"burst"`
+}
+
+// QuietHoursConfig defines a daily window, in "HH:MM" local time, during
+// which Low/Normal notifications are silenced. Start may be after End to
+// describe a window that wraps past midnight (e.g. 22:00 -> 07:00).
+type QuietHoursConfig struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// GovernorConfig configures the middleware that sits in front of the
+// pipeline: duplicate collapsing, per-app rate limiting and quiet hours.
+type GovernorConfig struct {
+	DedupWindowSeconds int               `json:"dedupWindowSeconds,omitempty"`
+	RateLimitPerApp    *RateLimitConfig  `json:"rateLimitPerApp,omitempty"`
+	QuietHours         *QuietHoursConfig `json:"quietHours,omitempty"`
+}
+
+// Config is the top-level pipeline configuration, loaded once at startup.
+type Config struct {
+	Notifiers NotifiersConfig `json:"notifiers"`
+	Governor  GovernorConfig  `json:"governor"`
+	Rules     []Rule          `json:"rules"`
+}
+
+// LoadConfig reads and compiles the pipeline config from path.
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	for i := range cfg.Rules {
+		r := &cfg.Rules[i]
+		if r.TitleRegex != "" {
+			re, err := regexp.Compile(r.TitleRegex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid titleRegex %q: %w", r.TitleRegex, err)
+			}
+			r.titleRegex = re
+		}
+		if r.MessageRegex != "" {
+			re, err := regexp.Compile(r.MessageRegex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid messageRegex %q: %w", r.MessageRegex, err)
+			}
+			r.messageRegex = re
+		}
+	}
+
+	return &cfg, nil
+}
+
+func (r Rule) matches(n notifier.Notification) bool {
+	if len(r.Urgencies) > 0 {
+		matched := false
+		for _, u := range r.Urgencies {
+			if strings.EqualFold(u, n.Urgency.String()) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if r.titleRegex != nil && !r.titleRegex.MatchString(n.Title) {
+		return false
+	}
+	if r.messageRegex != nil && !r.messageRegex.MatchString(n.Message) {
+		return false
+	}
+	return true
+}
+
+// notifierNames returns the names of the notifiers a notification should be
+// routed to, based on the first matching rule. If no rule matches, every
+// registered notifier is used so nothing is silently dropped.
+func (c *Config) notifierNames(n notifier.Notification, allNames []string) []string {
+	for _, r := range c.Rules {
+		if r.matches(n) {
+			return r.Notifiers
+		}
+	}
+	return allNames
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Neokil/go-notification-agent/notifier"
+)
+
+// Decision is the outcome of running a notification through the Governor.
+type Decision struct {
+	// Deliver is false if the notification should not be dispatched to the
+	// pipeline at all.
+	Deliver bool
+	// Duplicate is true if this notification collapsed into a repeat of an
+	// earlier one within the dedup window. Count is the new repeat total.
+	Duplicate bool
+	Count     int
+}
+
+type dedupKey struct {
+	App     string
+	Title   string
+	Message string
+}
+
+type dedupEntry struct {
+	count  int
+	seenAt time.Time
+}
+
+// tokenBucket is a classic token-bucket rate limiter: it refills at
+// refillRate tokens/second up to capacity, and Allow consumes one token.
+type tokenBucket struct {
+	tokens     float64
+	capacity   float64
+	refillRate float64
+	updatedAt  time.Time
+}
+
+func (b *tokenBucket) Allow(now time.Time) bool {
+	b.tokens += now.Sub(b.updatedAt).Seconds() * b.refillRate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.updatedAt = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Governor sits in front of the pipeline and decides whether an incoming
+// notification should be delivered: it collapses duplicates arriving within
+// a configurable window, enforces a per-app rate limit, and can silence
+// Low/Normal notifications during quiet hours -- either on the configured
+// schedule or a manual override set via the `dnd` socket command. High
+// urgency is never silenced by quiet hours, but is still subject to
+// duplicate collapsing and the per-app rate limit.
+type Governor struct {
+	mu sync.Mutex
+
+	dedupWindow time.Duration
+	dedup       map[dedupKey]*dedupEntry
+
+	rateLimit *RateLimitConfig
+	buckets   map[string]*tokenBucket
+
+	quietHours *QuietHoursConfig
+	dndForced  *bool
+	dndUntil   time.Time
+}
+
+// NewGovernor builds a Governor from its config section.
+func NewGovernor(cfg GovernorConfig) *Governor {
+	return &Governor{
+		dedupWindow: time.Duration(cfg.DedupWindowSeconds) * time.Second,
+		dedup:       map[dedupKey]*dedupEntry{},
+		rateLimit:   cfg.RateLimitPerApp,
+		buckets:     map[string]*tokenBucket{},
+		quietHours:  cfg.QuietHours,
+	}
+}
+
+// Admit decides whether a notification from app should be delivered.
+func (g *Governor) Admit(app string, n notifier.Notification) Decision {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := n.CreatedOn
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	if g.dedupWindow > 0 {
+		key := dedupKey{App: app, Title: n.Title, Message: n.Message}
+		if e, ok := g.dedup[key]; ok && now.Sub(e.seenAt) < g.dedupWindow {
+			e.count++
+			e.seenAt = now
+			return Decision{Duplicate: true, Count: e.count}
+		}
+		g.dedup[key] = &dedupEntry{count: 1, seenAt: now}
+	}
+
+	if n.Urgency != notifier.High && g.inQuietHoursLocked(now) {
+		return Decision{Deliver: false, Count: 1}
+	}
+
+	if g.rateLimit != nil && g.rateLimit.PerMinute > 0 {
+		b, ok := g.buckets[app]
+		if !ok {
+			b = &tokenBucket{
+				tokens:     float64(g.rateLimit.Burst),
+				capacity:   float64(g.rateLimit.Burst),
+				refillRate: float64(g.rateLimit.PerMinute) / 60,
+				updatedAt:  now,
+			}
+			g.buckets[app] = b
+		}
+		if !b.Allow(now) {
+			return Decision{Deliver: false, Count: 1}
+		}
+	}
+
+	return Decision{Deliver: true, Count: 1}
+}
+
+func (g *Governor) inQuietHoursLocked(now time.Time) bool {
+	if g.dndForced != nil {
+		if !g.dndUntil.IsZero() && now.After(g.dndUntil) {
+			g.dndForced = nil
+		} else {
+			return *g.dndForced
+		}
+	}
+
+	if g.quietHours == nil {
+		return false
+	}
+	start, err := time.ParseInLocation("15:04", g.quietHours.Start, now.Location())
+	if err != nil {
+		return false
+	}
+	end, err := time.ParseInLocation("15:04", g.quietHours.End, now.Location())
+	if err != nil {
+		return false
+	}
+
+	nowMinutes := now.Hour()*60 + now.Minute()
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+
+	if startMinutes <= endMinutes {
+		return nowMinutes >= startMinutes && nowMinutes < endMinutes
+	}
+	return nowMinutes >= startMinutes || nowMinutes < endMinutes
+}
+
+// SetDND manually overrides the quiet-hours schedule. A zero until means the
+// override stays in effect until SetDND is called again.
+func (g *Governor) SetDND(on bool, until time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.dndForced = &on
+	g.dndUntil = until
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Neokil/go-notification-agent/notifier"
+)
+
+func TestGovernorAdmitDeduplicatesWithinWindow(t *testing.T) {
+	g := NewGovernor(GovernorConfig{DedupWindowSeconds: 60})
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	n := notifier.Notification{Title: "t", Message: "m", Urgency: notifier.Normal, CreatedOn: base}
+
+	first := g.Admit("app", n)
+	if !first.Deliver || first.Duplicate {
+		t.Fatalf("expected first notification to be delivered, got %+v", first)
+	}
+
+	n.CreatedOn = base.Add(10 * time.Second)
+	second := g.Admit("app", n)
+	if !second.Duplicate || second.Count != 2 {
+		t.Fatalf("expected duplicate with count 2, got %+v", second)
+	}
+
+	n.CreatedOn = base.Add(2 * time.Minute)
+	third := g.Admit("app", n)
+	if third.Duplicate {
+		t.Fatalf("expected dedup window to have expired, got %+v", third)
+	}
+}
+
+func TestGovernorAdmitRateLimitsPerApp(t *testing.T) {
+	g := NewGovernor(GovernorConfig{
+		RateLimitPerApp: &RateLimitConfig{PerMinute: 60, Burst: 1},
+	})
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	admit := func(title string, at time.Time) Decision {
+		return g.Admit("app", notifier.Notification{Title: title, Message: "m", Urgency: notifier.Normal, CreatedOn: at})
+	}
+
+	first := admit("a", base)
+	if !first.Deliver {
+		t.Fatalf("expected first notification within burst to be delivered, got %+v", first)
+	}
+
+	second := admit("b", base)
+	if second.Deliver {
+		t.Fatalf("expected second notification to be rate-limited, got %+v", second)
+	}
+
+	third := admit("c", base.Add(time.Second))
+	if !third.Deliver {
+		t.Fatalf("expected bucket to have refilled a token after 1s at 1/s, got %+v", third)
+	}
+}
+
+func TestGovernorAdmitQuietHoursSilencesLowNormalNotHigh(t *testing.T) {
+	g := NewGovernor(GovernorConfig{
+		QuietHours: &QuietHoursConfig{Start: "22:00", End: "07:00"},
+	})
+
+	atNight := time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC)
+	normal := g.Admit("app", notifier.Notification{Title: "t", Message: "m", Urgency: notifier.Normal, CreatedOn: atNight})
+	if normal.Deliver {
+		t.Fatalf("expected Normal urgency to be silenced during quiet hours, got %+v", normal)
+	}
+
+	high := g.Admit("app", notifier.Notification{Title: "t2", Message: "m2", Urgency: notifier.High, CreatedOn: atNight})
+	if !high.Deliver {
+		t.Fatalf("expected High urgency to bypass quiet hours, got %+v", high)
+	}
+
+	atDay := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	daytime := g.Admit("app", notifier.Notification{Title: "t3", Message: "m3", Urgency: notifier.Normal, CreatedOn: atDay})
+	if !daytime.Deliver {
+		t.Fatalf("expected Normal urgency outside quiet hours to be delivered, got %+v", daytime)
+	}
+}
+
+func TestGovernorSetDNDManualOverride(t *testing.T) {
+	g := NewGovernor(GovernorConfig{})
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	g.SetDND(true, time.Time{})
+
+	decision := g.Admit("app", notifier.Notification{Title: "t", Message: "m", Urgency: notifier.Normal, CreatedOn: now})
+	if decision.Deliver {
+		t.Fatalf("expected manual dnd on to silence Normal urgency, got %+v", decision)
+	}
+
+	g.SetDND(false, time.Time{})
+	decision = g.Admit("app", notifier.Notification{Title: "t2", Message: "m2", Urgency: notifier.Normal, CreatedOn: now})
+	if !decision.Deliver {
+		t.Fatalf("expected manual dnd off to restore delivery, got %+v", decision)
+	}
+}
+
+func TestGovernorSetDNDUntilExpires(t *testing.T) {
+	g := NewGovernor(GovernorConfig{})
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	g.SetDND(true, now.Add(time.Minute))
+
+	before := g.Admit("app", notifier.Notification{Title: "t", Message: "m", Urgency: notifier.Normal, CreatedOn: now})
+	if before.Deliver {
+		t.Fatalf("expected dnd to be active before expiry, got %+v", before)
+	}
+
+	after := g.Admit("app", notifier.Notification{Title: "t2", Message: "m2", Urgency: notifier.Normal, CreatedOn: now.Add(2 * time.Minute)})
+	if !after.Deliver {
+		t.Fatalf("expected dnd override to have expired, got %+v", after)
+	}
+}
+
+func TestInQuietHoursLockedWrapsMidnight(t *testing.T) {
+	g := NewGovernor(GovernorConfig{QuietHours: &QuietHoursConfig{Start: "22:00", End: "07:00"}})
+
+	cases := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 1, 1, 21, 59, 0, 0, time.UTC), false},
+		{time.Date(2026, 1, 1, 22, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 1, 2, 6, 59, 0, 0, time.UTC), true},
+		{time.Date(2026, 1, 2, 7, 0, 0, 0, time.UTC), false},
+		{time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, c := range cases {
+		g.mu.Lock()
+		got := g.inQuietHoursLocked(c.at)
+		g.mu.Unlock()
+		if got != c.want {
+			t.Errorf("inQuietHoursLocked(%s) = %v, want %v", c.at.Format("2006-01-02 15:04"), got, c.want)
+		}
+	}
+}
@@ -2,172 +2,185 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
-	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strings"
-	"sync"
 	"syscall"
 	"time"
 
 	"github.com/godbus/dbus/v5"
+
+	"github.com/Neokil/go-notification-agent/notifier"
 )
 
 const SocketName = "/tmp/go-notification-agent.sock"
+const ConfigEnvVar = "GO_NOTIFICATION_AGENT_CONFIG"
+
+var bar *notifier.Bar
+var pipeline *Pipeline
+var store *Store
+var governor *Governor
+var shutdown context.CancelFunc
 
-type NotificationUrgency uint8
+func main() {
+	bar = notifier.NewBar()
 
-func (n NotificationUrgency) String() string {
-	switch n {
-	case Low:
-		return "Low"
-	case Normal:
-		return "Normal"
-	case High:
-		return "High"
-	default:
-		return fmt.Sprintf("Invalid NotificationUrgency: %d", n)
+	var err error
+	store, err = OpenStore(HistoryPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to open history store:", err)
+		os.Exit(1)
 	}
-}
+	defer store.Close()
 
-const (
-	Low    NotificationUrgency = 0
-	Normal NotificationUrgency = 1
-	High   NotificationUrgency = 2
-)
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to load config:", err)
+		os.Exit(1)
+	}
 
-type Notification struct {
-	Title     string
-	Message   string
-	Urgency   NotificationUrgency
-	CreatedOn time.Time
-}
+	notifiers := []notifier.Notifier{bar}
+	if desktop, err := notifier.NewDesktop(); err == nil {
+		notifiers = append(notifiers, desktop)
+	} else {
+		fmt.Fprintln(os.Stderr, "Desktop notifier disabled:", err)
+	}
+	notifiers = append(notifiers, buildConfiguredNotifiers(cfg)...)
 
-var notifications = []Notification{}
-var notificationsMutex = sync.RWMutex{}
+	governor = NewGovernor(cfg.Governor)
 
-var colorBarBackground string = "#000000"
-var colorBarText string = "#FFFFFF"
-var colorBarTextUrgent string = "#FF0000"
+	pipeline = NewPipeline(cfg, notifiers...)
+	pipeline.ReloadPlugins(PluginDir)
 
-func main() {
-	colorBarBackground, _ = GetXrdbValue("background")
-	colorBarText, _ = GetXrdbValue("foreground-alt")
-	colorBarTextUrgent, _ = GetXrdbValue("secondary")
+	ctx, cancel := context.WithCancel(context.Background())
+	shutdown = cancel
+
+	components := newComponentGroup()
+	components.Go("dbus", func() { listenForNotification(ctx) })
+	components.Go("socket", func() { listenToSocket(ctx) })
+	bar.Render()
 
-	go listenForNotification()
-	go listenToSocket()
-	printNotifications()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	select {
+	case <-sig:
+		cancel()
+	case <-ctx.Done():
+	}
 
-	<-c
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer shutdownCancel()
+	pipeline.Close(shutdownCtx)
+	components.Wait(ShutdownTimeout)
 }
 
-func listenToSocket() {
+// loadConfig loads the pipeline config from $GO_NOTIFICATION_AGENT_CONFIG, or
+// from the default XDG location if that variable is unset. A missing config
+// file is not an error: it results in an empty Config, which routes every
+// notification to all registered notifiers.
+func loadConfig() (*Config, error) {
+	path := os.Getenv(ConfigEnvVar)
+	if path == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return &Config{}, nil
+		}
+		path = filepath.Join(configDir, "go-notification-agent", "config.json")
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	return LoadConfig(path)
+}
+
+// buildConfiguredNotifiers instantiates the optional notifiers that are only
+// enabled once the user has filled in their config section.
+func buildConfiguredNotifiers(cfg *Config) []notifier.Notifier {
+	var notifiers []notifier.Notifier
 
+	if c := cfg.Notifiers.Bell; c != nil {
+		notifiers = append(notifiers, notifier.NewBell(c.SoundFile))
+	}
+	if c := cfg.Notifiers.Email; c != nil {
+		notifiers = append(notifiers, notifier.NewEmail(c.Host, c.Port, c.Username, c.Password, c.From, c.To))
+	}
+	if c := cfg.Notifiers.Webhook; c != nil {
+		notifiers = append(notifiers, notifier.NewWebhook(c.URL))
+	}
+
+	return notifiers
+}
+
+func listenToSocket(ctx context.Context) {
 	os.Remove(SocketName)
 	l, err := net.Listen("unix", SocketName)
 	if err != nil {
 		panic(err)
 	}
-	defer l.Close()
+	defer os.Remove(SocketName)
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
 
 	for {
 		con, err := l.Accept()
 		if err != nil {
-			panic(err)
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				fmt.Fprintln(os.Stderr, "Failed to accept connection:", err)
+				return
+			}
 		}
 		go handleConnection(con)
 	}
 }
 
+// handleConnection reads a single framed request, dispatches it, and writes
+// back its framed response. Each request is one JSON object terminated by
+// '\n'; so is each response.
 func handleConnection(c net.Conn) {
-	r := bufio.NewReader(c)
-	s, err := r.ReadString('\n')
+	defer c.Close()
+
+	line, err := bufio.NewReader(c).ReadString('\n')
 	if err != nil && !errors.Is(err, io.EOF) {
-		panic(err)
-	}
-	s = strings.TrimSpace(s)
-	switch s {
-	case "pop":
-		notificationsMutex.Lock()
-		if len(notifications) > 0 {
-			notifications = notifications[0 : len(notifications)-1]
-		}
-		notificationsMutex.Unlock()
-		_, _ = c.Write([]byte("Ok"))
-	case "clear":
-		notificationsMutex.Lock()
-		if len(notifications) > 0 {
-			notifications = []Notification{}
-		}
-		notificationsMutex.Unlock()
-		_, _ = c.Write([]byte("Ok"))
-	case "get-list":
-		notificationsMutex.RLock()
-		b, err := json.Marshal(notifications)
-		notificationsMutex.RUnlock()
-		if err != nil {
-			_, _ = c.Write([]byte("Error: " + err.Error()))
-		}
-		_, _ = c.Write(b)
-	case "exit":
-		_, _ = c.Write([]byte("Ok"))
-		os.Exit(0)
-	default:
-		_, _ = c.Write([]byte("Error: Unknown Command"))
+		fmt.Fprintln(os.Stderr, "Failed to read request:", err)
+		return
 	}
-
-	_, _ = c.Write([]byte("\n"))
-	c.Close()
-	printNotifications()
-}
-
-func printSeparator() {
-	fmt.Printf(" %%{F%s}%%{T2}%%{F%s}%%{T-} ", colorBarBackground, colorBarText)
-}
-
-func printNotifications() {
-	notificationsMutex.RLock()
-	defer notificationsMutex.RUnlock()
-
-	fmt.Printf("%d ", len(notifications))
-	if len(notifications) == 0 {
-		fmt.Print("\n")
+	line = strings.TrimSpace(line)
+	if line == "" {
 		return
 	}
-	printSeparator()
 
-	for i, n := range notifications {
-		if i > 0 {
-			printSeparator()
-		}
-		if i >= 3 {
-			break
-		}
-		text := n.Title + ": " + n.Message
-		if len(text) > 40 {
-			text = text[0:39] + "..."
-		}
-		if n.Urgency == High {
-			text = fmt.Sprintf("%%{F%s}%s%%{F%s}", colorBarTextUrgent, text, colorBarText)
-		}
-		fmt.Print(text)
+	var req Request
+	var resp Response
+	if err := json.Unmarshal([]byte(line), &req); err != nil {
+		resp = errResponse(fmt.Errorf("invalid request: %w", err))
+	} else {
+		resp = dispatch(req)
 	}
-	if len(notifications) > 3 {
-		fmt.Printf(" +%d", (len(notifications) - 3))
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		b = []byte(`{"ok":false,"error":"failed to marshal response"}`)
 	}
-	fmt.Print("\n")
+	_, _ = c.Write(append(b, '\n'))
+	bar.Render()
 }
 
-func listenForNotification() {
+func listenForNotification(ctx context.Context) {
 	conn, err := dbus.ConnectSessionBus()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Failed to connect to session bus:", err)
@@ -191,38 +204,60 @@ func listenForNotification() {
 
 	c := make(chan *dbus.Message, 10)
 	conn.Eavesdrop(c)
-	for v := range c {
-		if len(v.Body) < 7 {
-			continue
-		}
-		props := v.Body[6].(map[string]dbus.Variant)
-		urgency := Low
-		urgencyVariant, ok := props["urgency"]
-		if ok {
-			urgency = NotificationUrgency(urgencyVariant.Value().(uint8))
-		}
 
-		notificationsMutex.Lock()
-		notifications = append([]Notification{{
-			Title:     v.Body[3].(string),
-			Message:   v.Body[4].(string),
-			Urgency:   urgency,
-			CreatedOn: time.Now(),
-		}}, notifications...)
-		notificationsMutex.Unlock()
-		printNotifications()
-	}
-}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
 
-func GetXrdbValue(name string) (string, error) {
-	cmd := exec.Command("xrdb", "-get", name)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case v, ok := <-c:
+			if !ok {
+				return
+			}
+			if len(v.Body) < 7 {
+				continue
+			}
+			props := v.Body[6].(map[string]dbus.Variant)
+			urgency := notifier.Low
+			urgencyVariant, ok := props["urgency"]
+			if ok {
+				urgency = notifier.NotificationUrgency(urgencyVariant.Value().(uint8))
+			}
 
-	stderr := &strings.Builder{}
-	cmd.Stderr = stderr
+			app, _ := v.Body[0].(string)
+			if app == notifier.DesktopAppName {
+				// Our own Desktop notifier forwarding a call through
+				// org.freedesktop.Notifications.Notify; BecomeMonitor would
+				// otherwise recapture it and feed it back in, looping forever.
+				continue
+			}
+			title := v.Body[3].(string)
+			message := v.Body[4].(string)
 
-	b, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("Failed to get value from xrdb:%s: %w", stderr.String(), err)
+			n := notifier.Notification{
+				Title:     title,
+				Message:   message,
+				Urgency:   urgency,
+				CreatedOn: time.Now(),
+			}
+
+			decision := governor.Admit(app, n)
+			if decision.Duplicate {
+				store.BumpDuplicate(app, title, message, decision.Count)
+				continue
+			}
+
+			record := store.Append(title, message, app, urgency)
+			if !decision.Deliver {
+				continue
+			}
+			n.CreatedOn = record.CreatedOn
+			statuses := pipeline.Dispatch(ctx, n)
+			store.SetDelivery(record.ID, statuses)
+		}
 	}
-	return strings.TrimSpace(string(b)), nil
 }
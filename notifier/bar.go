@@ -0,0 +1,132 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Bar is the original polybar-style notifier: it keeps the notifications it
+// has seen and prints a condensed summary line to stdout whenever its state
+// changes, for consumption by a polybar `script` module.
+type Bar struct {
+	mu            sync.RWMutex
+	notifications []Notification
+
+	colorBackground string
+	colorText       string
+	colorTextUrgent string
+}
+
+// NewBar creates a Bar notifier, reading the bar's color scheme from xrdb so
+// it matches the rest of the desktop theme.
+func NewBar() *Bar {
+	b := &Bar{
+		colorBackground: "#000000",
+		colorText:       "#FFFFFF",
+		colorTextUrgent: "#FF0000",
+	}
+	if v, err := getXrdbValue("background"); err == nil {
+		b.colorBackground = v
+	}
+	if v, err := getXrdbValue("foreground-alt"); err == nil {
+		b.colorText = v
+	}
+	if v, err := getXrdbValue("secondary"); err == nil {
+		b.colorTextUrgent = v
+	}
+	return b
+}
+
+func (b *Bar) Name() string { return "bar" }
+
+func (b *Bar) Notify(ctx context.Context, n Notification) error {
+	b.mu.Lock()
+	b.notifications = append([]Notification{n}, b.notifications...)
+	b.mu.Unlock()
+	b.Render()
+	return nil
+}
+
+func (b *Bar) Close(ctx context.Context) error { return nil }
+
+// List returns the notifications currently tracked by the bar, most recent
+// first.
+func (b *Bar) List() []Notification {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	out := make([]Notification, len(b.notifications))
+	copy(out, b.notifications)
+	return out
+}
+
+// Pop drops the oldest tracked notification.
+func (b *Bar) Pop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.notifications) > 0 {
+		b.notifications = b.notifications[0 : len(b.notifications)-1]
+	}
+}
+
+// Clear drops all tracked notifications.
+func (b *Bar) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.notifications = []Notification{}
+}
+
+func (b *Bar) printSeparator() {
+	fmt.Printf(" %%{F%s}%%{T2}%%{F%s}%%{T-} ", b.colorBackground, b.colorText)
+}
+
+// Render prints the current summary line. It is called automatically after
+// every Notify, but is also exposed so callers can re-draw after mutating
+// the tracked notifications directly (Pop, Clear).
+func (b *Bar) Render() {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	fmt.Printf("%d ", len(b.notifications))
+	if len(b.notifications) == 0 {
+		fmt.Print("\n")
+		return
+	}
+	b.printSeparator()
+
+	for i, n := range b.notifications {
+		if i > 0 {
+			b.printSeparator()
+		}
+		if i >= 3 {
+			break
+		}
+		text := n.Title + ": " + n.Message
+		if len(text) > 40 {
+			text = text[0:39] + "..."
+		}
+		if n.Urgency == High {
+			text = fmt.Sprintf("%%{F%s}%s%%{F%s}", b.colorTextUrgent, text, b.colorText)
+		}
+		fmt.Print(text)
+	}
+	if len(b.notifications) > 3 {
+		fmt.Printf(" +%d", (len(b.notifications) - 3))
+	}
+	fmt.Print("\n")
+}
+
+func getXrdbValue(name string) (string, error) {
+	cmd := exec.Command("xrdb", "-get", name)
+
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+
+	b, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get value from xrdb:%s: %w", stderr.String(), err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
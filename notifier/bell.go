@@ -0,0 +1,29 @@
+package notifier
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Bell plays a short bundled sound file whenever a High-urgency notification
+// comes in. Anything below High is ignored.
+type Bell struct {
+	SoundFile string
+	Player    string
+}
+
+// NewBell creates a Bell notifier that plays soundFile through paplay.
+func NewBell(soundFile string) *Bell {
+	return &Bell{SoundFile: soundFile, Player: "paplay"}
+}
+
+func (b *Bell) Name() string { return "bell" }
+
+func (b *Bell) Notify(ctx context.Context, n Notification) error {
+	if n.Urgency != High {
+		return nil
+	}
+	return exec.CommandContext(ctx, b.Player, b.SoundFile).Run()
+}
+
+func (b *Bell) Close(ctx context.Context) error { return nil }
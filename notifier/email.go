@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+// Email forwards notifications as plain-text messages via an SMTP relay.
+type Email struct {
+	Host string
+	Port int
+	From string
+	To   []string
+
+	auth smtp.Auth
+}
+
+// NewEmail creates an Email notifier authenticating with username/password
+// against host:port.
+func NewEmail(host string, port int, username, password, from string, to []string) *Email {
+	return &Email{
+		Host: host,
+		Port: port,
+		From: from,
+		To:   to,
+		auth: smtp.PlainAuth("", username, password, host),
+	}
+}
+
+func (e *Email) Name() string { return "email" }
+
+// Notify dials and speaks SMTP by hand rather than using smtp.SendMail,
+// which has no way to take a context: an unresponsive or firewalled relay
+// would otherwise hang this call (and, with it, the whole pipeline) forever.
+// DialContext bounds the connect, and ctx's deadline (if any) bounds the
+// conversation that follows it.
+func (e *Email) Notify(ctx context.Context, n Notification) error {
+	addr := fmt.Sprintf("%s:%d", e.Host, e.Port)
+	body := fmt.Sprintf("Subject: [%s] %s\r\n\r\n%s\r\n", n.Urgency, n.Title, n.Message)
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to dial %s: %w", addr, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		return fmt.Errorf("failed to start smtp session with %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if e.auth != nil {
+		if err := client.Auth(e.auth); err != nil {
+			return fmt.Errorf("failed to authenticate with %s: %w", addr, err)
+		}
+	}
+	if err := client.Mail(e.From); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (e *Email) Close(ctx context.Context) error { return nil }
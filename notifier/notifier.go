@@ -0,0 +1,55 @@
+// Package notifier defines the pluggable notifier backend interface used by
+// the agent and the built-in implementations shipped with it.
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// NotificationUrgency mirrors the urgency levels defined by the
+// org.freedesktop.Notifications DBus interface.
+type NotificationUrgency uint8
+
+func (n NotificationUrgency) String() string {
+	switch n {
+	case Low:
+		return "Low"
+	case Normal:
+		return "Normal"
+	case High:
+		return "High"
+	default:
+		return fmt.Sprintf("Invalid NotificationUrgency: %d", n)
+	}
+}
+
+const (
+	Low    NotificationUrgency = 0
+	Normal NotificationUrgency = 1
+	High   NotificationUrgency = 2
+)
+
+// Notification is a single captured desktop notification.
+type Notification struct {
+	Title     string
+	Message   string
+	Urgency   NotificationUrgency
+	CreatedOn time.Time
+}
+
+// Notifier is a backend that notifications can be routed to. Built-in and
+// plugin implementations both satisfy this interface so the pipeline can
+// treat them interchangeably.
+type Notifier interface {
+	// Name identifies the notifier in config files and log output.
+	Name() string
+	// Notify delivers a single notification. Implementations that perform
+	// blocking I/O should respect ctx cancellation so shutdown isn't held up
+	// by a stuck delivery.
+	Notify(ctx context.Context, n Notification) error
+	// Close releases any resources held by the notifier. It is called once
+	// during shutdown, with a context bounding how long cleanup may take.
+	Close(ctx context.Context) error
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Neokil/go-notification-agent/notifier"
+)
+
+// DispatchTimeout bounds how long a single notifier gets to handle one
+// Notify or Close call. Notifiers are expected to respect ctx cancellation
+// (see the Notifier interface doc), but dispatchOne/Close also treat this as
+// a hard stop so a notifier that ignores ctx can't stall the rest of the
+// pipeline, or shutdown, indefinitely.
+const DispatchTimeout = 10 * time.Second
+
+// Pipeline routes incoming notifications to the notifiers selected by the
+// loaded Config.
+type Pipeline struct {
+	mu sync.RWMutex
+
+	config      *Config
+	notifiers   map[string]notifier.Notifier
+	order       []string
+	pluginNames []string
+}
+
+// NewPipeline registers notifiers under the names they report via Name() and
+// binds them to cfg.
+func NewPipeline(cfg *Config, notifiers ...notifier.Notifier) *Pipeline {
+	p := &Pipeline{
+		config:    cfg,
+		notifiers: map[string]notifier.Notifier{},
+	}
+	for _, n := range notifiers {
+		p.notifiers[n.Name()] = n
+		p.order = append(p.order, n.Name())
+	}
+	return p
+}
+
+// Dispatch delivers n to every notifier selected for it, logging rather than
+// failing on individual delivery errors so one broken notifier can't block
+// the others. It returns the delivery outcome per notifier name ("ok" or the
+// error text) so callers can persist it alongside the notification.
+func (p *Pipeline) Dispatch(ctx context.Context, n notifier.Notification) map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status := map[string]string{}
+	for _, name := range p.config.notifierNames(n, p.order) {
+		notif, ok := p.notifiers[name]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown notifier %q in config\n", name)
+			status[name] = "unknown notifier"
+			continue
+		}
+		status[name] = dispatchOne(ctx, notif, n)
+	}
+	return status
+}
+
+// dispatchOne runs a single notifier's Notify bounded by DispatchTimeout, so
+// one stuck delivery can't hold Dispatch's RLock open and block the rest of
+// the pipeline (including Close, which needs the write lock to shut down).
+func dispatchOne(ctx context.Context, notif notifier.Notifier, n notifier.Notification) string {
+	ctx, cancel := context.WithTimeout(ctx, DispatchTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- notif.Notify(ctx, n) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "notifier %q failed: %v\n", notif.Name(), err)
+			return err.Error()
+		}
+		return "ok"
+	case <-ctx.Done():
+		fmt.Fprintf(os.Stderr, "notifier %q timed out\n", notif.Name())
+		return "timed out"
+	}
+}
+
+// ReloadPlugins closes any notifiers previously loaded from dir and
+// registers a freshly loaded set in their place, so plugins can be hot-
+// swapped without restarting the agent.
+func (p *Pipeline) ReloadPlugins(dir string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, name := range p.pluginNames {
+		if n, ok := p.notifiers[name]; ok {
+			if err := n.Close(context.Background()); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to close plugin %q: %v\n", name, err)
+			}
+			delete(p.notifiers, name)
+		}
+	}
+	p.order = removeNames(p.order, p.pluginNames)
+	p.pluginNames = nil
+
+	for _, n := range loadPlugins(dir) {
+		p.notifiers[n.Name()] = n
+		p.order = append(p.order, n.Name())
+		p.pluginNames = append(p.pluginNames, n.Name())
+	}
+}
+
+// Names returns the names of every currently registered notifier.
+func (p *Pipeline) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	out := make([]string, len(p.order))
+	copy(out, p.order)
+	return out
+}
+
+// Close closes every registered notifier, collecting but not failing on
+// individual errors. Acquiring the write lock is itself bounded by ctx: a
+// Dispatch call stuck in a hung notifier holds the read lock for as long as
+// dispatchOne's timeout allows, and plain mu.Lock() has no way to time out on
+// its own, so Close would otherwise wait on it forever.
+func (p *Pipeline) Close(ctx context.Context) {
+	locked := make(chan struct{})
+	go func() {
+		p.mu.Lock()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+	case <-ctx.Done():
+		fmt.Fprintln(os.Stderr, "pipeline: timed out waiting for an in-flight dispatch, notifiers left open")
+		return
+	}
+	defer p.mu.Unlock()
+
+	for _, n := range p.notifiers {
+		closeCtx, cancel := context.WithTimeout(ctx, DispatchTimeout)
+		done := make(chan error, 1)
+		go func(n notifier.Notifier) { done <- n.Close(closeCtx) }(n)
+		select {
+		case err := <-done:
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to close notifier %q: %v\n", n.Name(), err)
+			}
+		case <-closeCtx.Done():
+			fmt.Fprintf(os.Stderr, "notifier %q timed out closing\n", n.Name())
+		}
+		cancel()
+	}
+}
+
+func removeNames(names, remove []string) []string {
+	if len(remove) == 0 {
+		return names
+	}
+	drop := make(map[string]bool, len(remove))
+	for _, n := range remove {
+		drop[n] = true
+	}
+	out := names[:0]
+	for _, n := range names {
+		if !drop[n] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sync/atomic"
+
+	"github.com/Neokil/go-notification-agent/notifier"
+)
+
+// PluginDir is the default directory scanned for notifier plugins.
+const PluginDir = "plugins"
+
+// loadPlugins opens every *.so file in dir and looks up an exported Caller
+// symbol implementing notifier.Notifier. This lets users ship private
+// notifier integrations (PagerDuty, Discord, custom LED strips, ...) without
+// forking the agent. Plugins that fail to load or don't satisfy the
+// interface are skipped with a logged warning rather than aborting startup.
+func loadPlugins(dir string) []notifier.Notifier {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Fprintln(os.Stderr, "Failed to read plugin directory:", err)
+		}
+		return nil
+	}
+
+	var loaded []notifier.Notifier
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".so" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		n, err := loadPlugin(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load plugin %s: %v\n", path, err)
+			continue
+		}
+		loaded = append(loaded, n)
+	}
+	return loaded
+}
+
+// pluginOpenCounter gives each loadPlugin call a distinct staging path, so a
+// .so rebuilt at the same source path still gets opened fresh.
+var pluginOpenCounter uint64
+
+// loadPlugin opens a single plugin file and resolves its exported Caller
+// symbol against the notifier.Notifier interface.
+//
+// plugin.Open caches by the path it's given and hands back the already-
+// loaded *Plugin (and its already-resolved symbols) for a path it has seen
+// before, which would make reload-plugins a silent no-op for a .so rebuilt
+// in place. loadPlugin works around this by opening a throwaway copy at a
+// path that's unique to this call instead of opening path directly.
+func loadPlugin(path string) (notifier.Notifier, error) {
+	staged, err := stagePluginCopy(path)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(staged)
+
+	p, err := plugin.Open(staged)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup("Caller")
+	if err != nil {
+		return nil, err
+	}
+	n, ok := sym.(notifier.Notifier)
+	if !ok {
+		return nil, fmt.Errorf("exported Caller symbol does not implement notifier.Notifier")
+	}
+	return n, nil
+}
+
+// stagePluginCopy copies path into the system temp directory under a name
+// that's unique to this call and returns its path. The copy is safe to
+// remove as soon as plugin.Open returns: on Linux, Open mmaps the shared
+// object, and an mmap'd file keeps working after its directory entry is
+// unlinked.
+func stagePluginCopy(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	id := atomic.AddUint64(&pluginOpenCounter, 1)
+	staged := filepath.Join(os.TempDir(), fmt.Sprintf("go-notification-agent-plugin-%d-%s", id, filepath.Base(path)))
+
+	dst, err := os.OpenFile(staged, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o700)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		os.Remove(staged)
+		return "", err
+	}
+	return staged, nil
+}
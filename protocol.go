@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ProtocolVersion is bumped whenever the request or response schema changes
+// in a way clients need to know about. Clients can check it via the
+// `capabilities` command.
+const ProtocolVersion = 1
+
+// Request is a single command sent over the socket as one JSON object per
+// line: {"cmd": "...", "args": {...}}.
+type Request struct {
+	Cmd  string          `json:"cmd"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// Response is returned for every Request, also as one JSON object per line.
+type Response struct {
+	Ok    bool        `json:"ok"`
+	Error string      `json:"error,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+func ok(data interface{}) Response { return Response{Ok: true, Data: data} }
+
+func errResponse(err error) Response { return Response{Ok: false, Error: err.Error()} }
+
+// historyArgs is the payload accepted by the `history` command.
+type historyArgs struct {
+	Since   string `json:"since,omitempty"`
+	Urgency string `json:"urgency,omitempty"`
+	App     string `json:"app,omitempty"`
+}
+
+// ackArgs is the payload accepted by the `ack` command.
+type ackArgs struct {
+	ID uint64 `json:"id"`
+}
+
+// dndArgs is the payload accepted by the `dnd` command.
+type dndArgs struct {
+	State string `json:"state"`
+	Until string `json:"until,omitempty"`
+}
+
+// capabilitiesData is returned by the `capabilities` command.
+type capabilitiesData struct {
+	Version   int      `json:"version"`
+	Commands  []string `json:"commands"`
+	Notifiers []string `json:"notifiers"`
+}
+
+var supportedCommands = []string{
+	"pop", "clear", "get-list", "reload-plugins",
+	"history", "ack", "stats", "dnd", "capabilities", "exit",
+}
+
+// dispatch executes a single decoded Request and builds its Response.
+func dispatch(req Request) Response {
+	switch req.Cmd {
+	case "pop":
+		bar.Pop()
+		return ok(nil)
+	case "clear":
+		bar.Clear()
+		return ok(nil)
+	case "get-list":
+		return ok(bar.List())
+	case "reload-plugins":
+		pipeline.ReloadPlugins(PluginDir)
+		return ok(nil)
+	case "history":
+		var a historyArgs
+		if err := unmarshalArgs(req.Args, &a); err != nil {
+			return errResponse(err)
+		}
+		var since time.Duration
+		if a.Since != "" {
+			d, err := time.ParseDuration(a.Since)
+			if err != nil {
+				return errResponse(fmt.Errorf("invalid since %q: %w", a.Since, err))
+			}
+			since = d
+		}
+		return ok(store.Query(since, a.Urgency, a.App))
+	case "ack":
+		var a ackArgs
+		if err := unmarshalArgs(req.Args, &a); err != nil {
+			return errResponse(err)
+		}
+		if !store.Ack(a.ID) {
+			return errResponse(fmt.Errorf("unknown id %d", a.ID))
+		}
+		return ok(nil)
+	case "stats":
+		return ok(store.Stats())
+	case "dnd":
+		var a dndArgs
+		if err := unmarshalArgs(req.Args, &a); err != nil {
+			return errResponse(err)
+		}
+		switch a.State {
+		case "on":
+			governor.SetDND(true, time.Time{})
+		case "off":
+			governor.SetDND(false, time.Time{})
+		case "until":
+			d, err := time.ParseDuration(a.Until)
+			if err != nil {
+				return errResponse(fmt.Errorf("invalid until %q: %w", a.Until, err))
+			}
+			governor.SetDND(true, time.Now().Add(d))
+		default:
+			return errResponse(fmt.Errorf("state must be one of on, off, until"))
+		}
+		return ok(nil)
+	case "capabilities":
+		return ok(capabilitiesData{
+			Version:   ProtocolVersion,
+			Commands:  supportedCommands,
+			Notifiers: pipeline.Names(),
+		})
+	case "exit":
+		shutdown()
+		return ok(nil)
+	default:
+		return errResponse(fmt.Errorf("unknown command %q", req.Cmd))
+	}
+}
+
+// unmarshalArgs decodes raw into v, treating a missing args field as a no-op
+// rather than an error since several commands take no arguments.
+func unmarshalArgs(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid args: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/Neokil/go-notification-agent/notifier"
+)
+
+// setupDispatchTest points the package-level globals dispatch relies on at
+// fresh, isolated state, and restores the previous ones once the test ends.
+func setupDispatchTest(t *testing.T) {
+	t.Helper()
+
+	prevBar, prevPipeline, prevStore, prevGovernor, prevShutdown := bar, pipeline, store, governor, shutdown
+	t.Cleanup(func() {
+		store.Close()
+		bar, pipeline, store, governor, shutdown = prevBar, prevPipeline, prevStore, prevGovernor, prevShutdown
+	})
+
+	bar = notifier.NewBar()
+
+	s, err := OpenStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	store = s
+
+	governor = NewGovernor(GovernorConfig{})
+	pipeline = NewPipeline(&Config{}, bar)
+	shutdown = func() {}
+}
+
+func TestDispatchPopClearGetList(t *testing.T) {
+	setupDispatchTest(t)
+
+	bar.Notify(context.Background(), notifier.Notification{Title: "a", Message: "b"})
+
+	if resp := dispatch(Request{Cmd: "get-list"}); !resp.Ok {
+		t.Fatalf("expected get-list to succeed, got %+v", resp)
+	}
+	if resp := dispatch(Request{Cmd: "pop"}); !resp.Ok {
+		t.Fatalf("expected pop to succeed, got %+v", resp)
+	}
+	if resp := dispatch(Request{Cmd: "clear"}); !resp.Ok {
+		t.Fatalf("expected clear to succeed, got %+v", resp)
+	}
+}
+
+func TestDispatchUnknownCommand(t *testing.T) {
+	setupDispatchTest(t)
+
+	resp := dispatch(Request{Cmd: "nope"})
+	if resp.Ok {
+		t.Fatalf("expected unknown command to fail, got %+v", resp)
+	}
+}
+
+func TestDispatchHistoryAndAck(t *testing.T) {
+	setupDispatchTest(t)
+
+	record := store.Append("t", "m", "app", notifier.Normal)
+
+	historyReq, _ := json.Marshal(historyArgs{App: "app"})
+	if resp := dispatch(Request{Cmd: "history", Args: historyReq}); !resp.Ok {
+		t.Fatalf("expected history to succeed, got %+v", resp)
+	}
+
+	ackReq, _ := json.Marshal(ackArgs{ID: record.ID})
+	if resp := dispatch(Request{Cmd: "ack", Args: ackReq}); !resp.Ok {
+		t.Fatalf("expected ack to succeed, got %+v", resp)
+	}
+
+	unknownReq, _ := json.Marshal(ackArgs{ID: record.ID + 1})
+	if resp := dispatch(Request{Cmd: "ack", Args: unknownReq}); resp.Ok {
+		t.Fatalf("expected ack of unknown id to fail, got %+v", resp)
+	}
+}
+
+func TestDispatchStats(t *testing.T) {
+	setupDispatchTest(t)
+
+	store.Append("t", "m", "app", notifier.Normal)
+
+	resp := dispatch(Request{Cmd: "stats"})
+	if !resp.Ok {
+		t.Fatalf("expected stats to succeed, got %+v", resp)
+	}
+	stats, ok := resp.Data.(Stats)
+	if !ok {
+		t.Fatalf("expected Stats, got %T", resp.Data)
+	}
+	if stats.Total != 1 {
+		t.Fatalf("expected total 1, got %d", stats.Total)
+	}
+}
+
+func TestDispatchDND(t *testing.T) {
+	setupDispatchTest(t)
+
+	onReq, _ := json.Marshal(dndArgs{State: "on"})
+	if resp := dispatch(Request{Cmd: "dnd", Args: onReq}); !resp.Ok {
+		t.Fatalf("expected dnd on to succeed, got %+v", resp)
+	}
+
+	invalidReq, _ := json.Marshal(dndArgs{State: "bogus"})
+	if resp := dispatch(Request{Cmd: "dnd", Args: invalidReq}); resp.Ok {
+		t.Fatalf("expected invalid dnd state to fail, got %+v", resp)
+	}
+
+	badUntilReq, _ := json.Marshal(dndArgs{State: "until", Until: "not-a-duration"})
+	if resp := dispatch(Request{Cmd: "dnd", Args: badUntilReq}); resp.Ok {
+		t.Fatalf("expected invalid until to fail, got %+v", resp)
+	}
+}
+
+func TestDispatchCapabilities(t *testing.T) {
+	setupDispatchTest(t)
+
+	resp := dispatch(Request{Cmd: "capabilities"})
+	if !resp.Ok {
+		t.Fatalf("expected capabilities to succeed, got %+v", resp)
+	}
+	data, ok := resp.Data.(capabilitiesData)
+	if !ok {
+		t.Fatalf("expected capabilitiesData, got %T", resp.Data)
+	}
+	if data.Version != ProtocolVersion {
+		t.Fatalf("expected protocol version %d, got %d", ProtocolVersion, data.Version)
+	}
+}
+
+func TestDispatchExitCallsShutdown(t *testing.T) {
+	setupDispatchTest(t)
+
+	called := false
+	shutdown = func() { called = true }
+
+	resp := dispatch(Request{Cmd: "exit"})
+	if !resp.Ok {
+		t.Fatalf("expected exit to succeed, got %+v", resp)
+	}
+	if !called {
+		t.Fatalf("expected shutdown to be called")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ShutdownTimeout bounds how long components get to react to context
+// cancellation before main logs which of them are still outstanding and
+// exits anyway.
+const ShutdownTimeout = 5 * time.Second
+
+// componentGroup is a sync.WaitGroup wrapper that remembers the name each
+// tracked goroutine was registered under, so a shutdown that times out can
+// report exactly which component is still running instead of just hanging.
+type componentGroup struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	pending map[string]int
+}
+
+func newComponentGroup() *componentGroup {
+	return &componentGroup{pending: map[string]int{}}
+}
+
+// Go runs fn in a new goroutine tracked under name.
+func (g *componentGroup) Go(name string, fn func()) {
+	g.mu.Lock()
+	g.pending[name]++
+	g.mu.Unlock()
+	g.wg.Add(1)
+
+	go func() {
+		defer g.done(name)
+		fn()
+	}()
+}
+
+func (g *componentGroup) done(name string) {
+	g.mu.Lock()
+	g.pending[name]--
+	if g.pending[name] <= 0 {
+		delete(g.pending, name)
+	}
+	g.mu.Unlock()
+	g.wg.Done()
+}
+
+// Wait blocks until every tracked component has returned, or until timeout
+// elapses. If it times out, it logs the names of the components that are
+// still outstanding so a hung shutdown is diagnosable instead of silent.
+func (g *componentGroup) Wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		g.mu.Lock()
+		for name, n := range g.pending {
+			fmt.Fprintf(os.Stderr, "shutdown: %s still running (%d)\n", name, n)
+		}
+		g.mu.Unlock()
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Neokil/go-notification-agent/notifier"
+)
+
+// HistoryPath is the default location of the append-only notification log.
+const HistoryPath = "/tmp/go-notification-agent.history.jsonl"
+
+// Record is a single notification as tracked by the history store, extended
+// with the bookkeeping the bar notifier doesn't need: a stable id, the
+// source application, per-notifier delivery status, and a repeat count for
+// notifications the Governor collapsed as duplicates.
+type Record struct {
+	ID        uint64                       `json:"id"`
+	Title     string                       `json:"title"`
+	Message   string                       `json:"message"`
+	Urgency   notifier.NotificationUrgency `json:"urgency"`
+	App       string                       `json:"app"`
+	CreatedOn time.Time                    `json:"createdOn"`
+	Delivery  map[string]string            `json:"delivery"`
+	Acked     bool                         `json:"acked"`
+	Count     int                          `json:"count"`
+}
+
+// Stats is a summary of everything the history store has captured so far.
+type Stats struct {
+	Total     int            `json:"total"`
+	Unacked   int            `json:"unacked"`
+	ByApp     map[string]int `json:"byApp"`
+	ByUrgency map[string]int `json:"byUrgency"`
+}
+
+// Store is an append-only JSONL log of every notification the agent has
+// captured. Updating a record (delivery status, ack) appends a new line for
+// its id rather than rewriting the file; replay keeps only the last line
+// seen per id.
+type Store struct {
+	mu      sync.Mutex
+	file    *os.File
+	nextID  uint64
+	records []Record
+	byID    map[uint64]int
+}
+
+// OpenStore opens (creating if necessary) the history log at path and
+// replays it into memory so queries don't need to re-read the file.
+func OpenStore(path string) (*Store, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history store %s: %w", path, err)
+	}
+
+	s := &Store{file: f, byID: map[uint64]int{}}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		if idx, ok := s.byID[r.ID]; ok {
+			s.records[idx] = r
+		} else {
+			s.byID[r.ID] = len(s.records)
+			s.records = append(s.records, r)
+		}
+		if r.ID >= s.nextID {
+			s.nextID = r.ID + 1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to replay history store %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// Append records a newly captured notification and returns it with its
+// assigned id.
+func (s *Store) Append(title, message, app string, urgency notifier.NotificationUrgency) Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := Record{
+		ID:        s.nextID,
+		Title:     title,
+		Message:   message,
+		Urgency:   urgency,
+		App:       app,
+		CreatedOn: time.Now(),
+		Delivery:  map[string]string{},
+		Count:     1,
+	}
+	s.nextID++
+	s.byID[r.ID] = len(s.records)
+	s.records = append(s.records, r)
+	s.writeLocked(r)
+	return r
+}
+
+// SetDelivery records the delivery outcome of a notifier for a previously
+// appended record.
+func (s *Store) SetDelivery(id uint64, statuses map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, ok := s.byID[id]
+	if !ok {
+		return
+	}
+	for name, status := range statuses {
+		s.records[idx].Delivery[name] = status
+	}
+	s.writeLocked(s.records[idx])
+}
+
+// BumpDuplicate sets the repeat Count on the most recent record matching
+// (app, title, message), for a notification the Governor collapsed as a
+// duplicate rather than appending a new record for it.
+func (s *Store) BumpDuplicate(app, title, message string, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.records) - 1; i >= 0; i-- {
+		r := &s.records[i]
+		if r.App == app && r.Title == title && r.Message == message {
+			r.Count = count
+			s.writeLocked(*r)
+			return
+		}
+	}
+}
+
+// Ack marks a record as read. It reports whether id was known.
+func (s *Store) Ack(id uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, ok := s.byID[id]
+	if !ok {
+		return false
+	}
+	s.records[idx].Acked = true
+	s.writeLocked(s.records[idx])
+	return true
+}
+
+// Query returns the records matching the given filters, most recent first.
+// A zero since, empty urgency or empty appGlob leaves that filter unapplied.
+func (s *Store) Query(since time.Duration, urgency, appGlob string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var cutoff time.Time
+	if since > 0 {
+		cutoff = time.Now().Add(-since)
+	}
+
+	var out []Record
+	for i := len(s.records) - 1; i >= 0; i-- {
+		r := s.records[i]
+		if !cutoff.IsZero() && r.CreatedOn.Before(cutoff) {
+			continue
+		}
+		if urgency != "" && !strings.EqualFold(r.Urgency.String(), urgency) {
+			continue
+		}
+		if appGlob != "" {
+			if ok, _ := filepath.Match(appGlob, r.App); !ok {
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// Stats summarizes every record the store has captured.
+func (s *Store) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := Stats{ByApp: map[string]int{}, ByUrgency: map[string]int{}}
+	for _, r := range s.records {
+		stats.Total++
+		if !r.Acked {
+			stats.Unacked++
+		}
+		stats.ByApp[r.App]++
+		stats.ByUrgency[r.Urgency.String()]++
+	}
+	return stats
+}
+
+// Close closes the underlying log file.
+func (s *Store) Close() error {
+	return s.file.Close()
+}
+
+func (s *Store) writeLocked(r Record) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to marshal history record:", err)
+		return
+	}
+	if _, err := s.file.Write(append(b, '\n')); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to append history record:", err)
+	}
+}